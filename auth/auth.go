@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 )
 
@@ -36,8 +39,10 @@ type TokenAuthTransport struct {
 }
 
 // cloneRequest returns a clone of the provided *http.Request.
-// The clone is a shallow copy of the struct and its Header map.
-func cloneRequest(r *http.Request) *http.Request {
+// The clone is a shallow copy of the struct, its Header map, and, if
+// present, its Body — so retries and refresh POSTs that re-issue the
+// request don't send an already-consumed, empty body.
+func cloneRequest(r *http.Request) (*http.Request, error) {
 	// shallow copy of the struct
 	r2 := new(http.Request)
 	*r2 = *r
@@ -46,7 +51,22 @@ func cloneRequest(r *http.Request) *http.Request {
 	for k, s := range r.Header {
 		r2.Header[k] = append([]string(nil), s...)
 	}
-	return r2
+
+	if r.Body == nil {
+		return r2, nil
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("clone request error: %w", err)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r2.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r2.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	r2.ContentLength = int64(len(body))
+	return r2, nil
 }
 
 // RoundTrip implements the RoundTripper interface.
@@ -54,7 +74,10 @@ func (t *TokenAuthTransport) RoundTrip(req *http.Request) (*http.Response, error
 	// To set extra headers, we must make a copy of the Request so
 	// that we don't modify the Request we were given. This is required by the
 	// specification of http.RoundTripper.
-	req2 := cloneRequest(req)
+	req2, err := cloneRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("roundtrip error: %w", err)
+	}
 	if t.Source == nil {
 		return nil, errors.New("auth: Transport's Source is nil")
 	}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type fakeToken struct{}
+
+func (fakeToken) Valid() bool { return true }
+
+func (fakeToken) SetAuthorization(req *http.Request) {
+	req.Header.Set("Authorization", "Token abc")
+}
+
+type fakeTokenSource struct{}
+
+func (fakeTokenSource) Token() (Token, error) { return fakeToken{}, nil }
+
+type capturingRoundTripper struct {
+	bodies []string
+}
+
+func (c *capturingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.bodies = append(c.bodies, string(body))
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+}
+
+func TestTokenAuthTransportRoundTripReplaysBodyAcrossAttempts(t *testing.T) {
+	capture := &capturingRoundTripper{}
+	transport := &TokenAuthTransport{Source: fakeTokenSource{}, Transport: capture}
+
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+
+	if len(capture.bodies) != 2 {
+		t.Fatalf("len(bodies) = %d, want 2", len(capture.bodies))
+	}
+	for i, body := range capture.bodies {
+		if body != "payload" {
+			t.Fatalf("attempt %d body = %q, want %q", i, body, "payload")
+		}
+	}
+}
+
+func TestTokenAuthTransportRoundTripDoesNotMutateCallerRequestHeaders(t *testing.T) {
+	capture := &capturingRoundTripper{}
+	transport := &TokenAuthTransport{Source: fakeTokenSource{}, Transport: capture}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Fatalf("caller's request Authorization header = %q, want unset (RoundTrip must not mutate the original request)", got)
+	}
+}
@@ -1,12 +1,15 @@
 package auth
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,6 +17,7 @@ import (
 type JWTToken struct {
 	token     string
 	expiredAt int64
+	scheme    string
 }
 
 // NewJWTToken create a jwt token with token string.
@@ -41,7 +45,11 @@ func (t *JWTToken) almostExpired() bool {
 
 // SetAuthorization set http request Authorization header.
 func (t *JWTToken) SetAuthorization(req *http.Request) {
-	req.Header.Set("Authorization", "Token "+t.token)
+	scheme := t.scheme
+	if scheme == "" {
+		scheme = "Token"
+	}
+	req.Header.Set("Authorization", scheme+" "+t.token)
 }
 
 // SetExpireTime set expire time.
@@ -67,3 +75,126 @@ func (t *JWTToken) SetExpireTime() error {
 	}
 	return nil
 }
+
+// loginRequest is the default JSON body posted to a JWTAuthSource's LoginURL.
+// It is only used when LoginBody is nil.
+type loginRequest struct {
+	MachineID string   `json:"machine_id"`
+	Password  string   `json:"password"`
+	Scenarios []string `json:"scenarios,omitempty"`
+}
+
+// loginResponse is the expected shape of a JWTAuthSource login response.
+type loginResponse struct {
+	Token  string `json:"token"`
+	Expire string `json:"expire"`
+}
+
+// JWTAuthSource is a TokenSource that logs in against a login endpoint to
+// obtain a JWTToken, and transparently re-logs in once the cached token is
+// missing or almost expired.
+type JWTAuthSource struct {
+	// MachineID, Password and Scenarios build the default login body.
+	// They are ignored when LoginBody is set.
+	MachineID string
+	Password  string
+	Scenarios []string
+
+	// LoginBody, when set, is marshaled as JSON and posted instead of the
+	// default MachineID/Password/Scenarios body.
+	LoginBody interface{}
+
+	// LoginURL is the endpoint credentials are posted to.
+	LoginURL string
+
+	// Scheme is the Authorization scheme used by tokens obtained through
+	// this source, e.g. "Bearer" or "Token". Defaults to "Token".
+	Scheme string
+
+	// Transport is the underlying HTTP transport used to log in.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+
+	mu    sync.Mutex
+	token *JWTToken
+}
+
+// Token returns a token or an error.
+func (s *JWTAuthSource) Token() (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	t, err := s.login()
+	if err != nil {
+		return nil, fmt.Errorf("jwt auth source error: %w", err)
+	}
+	s.token = t
+	return t, nil
+}
+
+// login posts credentials to LoginURL and returns the resulting JWTToken.
+func (s *JWTAuthSource) login() (*JWTToken, error) {
+	body := s.LoginBody
+	if body == nil {
+		body = loginRequest{MachineID: s.MachineID, Password: s.Password, Scenarios: s.Scenarios}
+	}
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(body); err != nil {
+		return nil, fmt.Errorf("encode login body error: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.LoginURL, buf)
+	if err != nil {
+		return nil, fmt.Errorf("new login request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.transport().RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("login request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read login response error: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("login request returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var lr loginResponse
+	if err := json.Unmarshal(respBody, &lr); err != nil {
+		return nil, fmt.Errorf("unmarshal login response error: %w", err)
+	}
+
+	t, err := NewJWTToken(lr.Token)
+	if err != nil {
+		return nil, fmt.Errorf("parse login token error: %w", err)
+	}
+	if t.expiredAt == 0 && lr.Expire != "" {
+		if exp, err := time.Parse(time.RFC3339, lr.Expire); err == nil {
+			t.expiredAt = exp.Unix()
+		}
+	}
+	t.scheme = s.scheme()
+	return t, nil
+}
+
+func (s *JWTAuthSource) transport() http.RoundTripper {
+	if s.Transport != nil {
+		return s.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (s *JWTAuthSource) scheme() string {
+	if s.Scheme != "" {
+		return s.Scheme
+	}
+	return "Token"
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// jwtWithExp builds a syntactically valid (unsigned) JWT whose payload
+// carries the given exp claim, for exercising SetExpireTime.
+func jwtWithExp(exp int64) string {
+	header := base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString([]byte(`{"alg":"none"}`))
+	payload := base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp)))
+	return header + "." + payload + ".sig"
+}
+
+func loginResponder(t *testing.T, token string) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("read login request body error: %v", err)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("unmarshal login request body error: %v", err)
+		}
+
+		respBody, _ := json.Marshal(loginResponse{Token: token})
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(respBody)),
+		}, nil
+	}
+}
+
+func TestJWTAuthSourceLoginAndCache(t *testing.T) {
+	token := jwtWithExp(time.Now().Add(time.Hour).Unix())
+	calls := 0
+	source := &JWTAuthSource{
+		MachineID: "machine-1",
+		Password:  "secret",
+		LoginURL:  "http://login.example.com/login",
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return loginResponder(t, token)(req)
+		}),
+	}
+
+	got, err := source.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if !got.Valid() {
+		t.Fatal("Token() returned an invalid token")
+	}
+	if _, err := source.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("login calls = %d, want 1 (valid cached token should not re-login)", calls)
+	}
+}
+
+func TestJWTAuthSourceRelogsInWhenExpired(t *testing.T) {
+	calls := 0
+	source := &JWTAuthSource{
+		MachineID: "machine-1",
+		Password:  "secret",
+		LoginURL:  "http://login.example.com/login",
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			exp := time.Now().Add(-time.Hour).Unix()
+			if calls > 1 {
+				exp = time.Now().Add(time.Hour).Unix()
+			}
+			return loginResponder(t, jwtWithExp(exp))(req)
+		}),
+	}
+
+	if _, err := source.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if _, err := source.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("login calls = %d, want 2 (expired cached token should trigger a re-login)", calls)
+	}
+}
+
+func TestJWTAuthSourceSchemeDefaultsToToken(t *testing.T) {
+	token := jwtWithExp(time.Now().Add(time.Hour).Unix())
+	source := &JWTAuthSource{
+		LoginURL:  "http://login.example.com/login",
+		Transport: roundTripFunc(loginResponder(t, token)),
+	}
+
+	tok, err := source.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	tok.SetAuthorization(req)
+	if got := req.Header.Get("Authorization"); got[:len("Token ")] != "Token " {
+		t.Fatalf("Authorization = %q, want Token scheme", got)
+	}
+}
+
+func TestJWTAuthSourceCustomScheme(t *testing.T) {
+	token := jwtWithExp(time.Now().Add(time.Hour).Unix())
+	source := &JWTAuthSource{
+		LoginURL:  "http://login.example.com/login",
+		Scheme:    "Bearer",
+		Transport: roundTripFunc(loginResponder(t, token)),
+	}
+
+	tok, err := source.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	tok.SetAuthorization(req)
+	if got := req.Header.Get("Authorization"); got[:len("Bearer ")] != "Bearer " {
+		t.Fatalf("Authorization = %q, want Bearer scheme", got)
+	}
+}
+
+func TestJWTAuthSourceCustomLoginBody(t *testing.T) {
+	type apiKeyLogin struct {
+		APIKey string `json:"api_key"`
+	}
+	token := jwtWithExp(time.Now().Add(time.Hour).Unix())
+
+	var gotBody apiKeyLogin
+	source := &JWTAuthSource{
+		LoginBody: apiKeyLogin{APIKey: "xyz"},
+		LoginURL:  "http://login.example.com/login",
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				t.Fatalf("read login request body error: %v", err)
+			}
+			if err := json.Unmarshal(body, &gotBody); err != nil {
+				t.Fatalf("unmarshal login request body error: %v", err)
+			}
+			respBody, _ := json.Marshal(loginResponse{Token: token})
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewReader(respBody))}, nil
+		}),
+	}
+
+	if _, err := source.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if gotBody.APIKey != "xyz" {
+		t.Fatalf("login body api_key = %q, want xyz", gotBody.APIKey)
+	}
+}
@@ -0,0 +1,285 @@
+// Package oauth2 implements an OAuth2 client-credentials / refresh-token
+// flow that produces auth.Token values consumable by auth.TokenAuthTransport.
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/teletraan/httpx/auth"
+)
+
+// AuthStyle describes how the client id and secret are sent in a token request.
+type AuthStyle int
+
+const (
+	// AuthStyleHeader sends client id and secret via HTTP Basic Authentication.
+	AuthStyleHeader AuthStyle = iota
+	// AuthStyleBody sends client id and secret as part of the request body.
+	AuthStyleBody
+)
+
+// Config describes an OAuth2 client-credentials / refresh-token flow.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+
+	// TokenURL is the endpoint used to obtain or refresh a token.
+	TokenURL string
+
+	// AuthURL is the authorization endpoint used by AuthCodeURL for
+	// three-legged flows. It is not required for the client-credentials flow.
+	AuthURL string
+
+	Scopes []string
+
+	// EndpointParams are additional parameters included in every token request.
+	EndpointParams url.Values
+
+	// AuthStyle controls how ClientID/ClientSecret are sent to TokenURL.
+	AuthStyle AuthStyle
+}
+
+// Token represents an OAuth2 bearer token.
+type Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// Valid return whehter token valid.
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && !t.expired()
+}
+
+// expired reports whether the token is expired after 10s.
+// t must be non-nil.
+func (t *Token) expired() bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+	return time.Now().Add(10 * time.Second).After(t.Expiry)
+}
+
+// SetAuthorization set http request Authorization header.
+func (t *Token) SetAuthorization(req *http.Request) {
+	typ := t.TokenType
+	if typ == "" {
+		typ = "Bearer"
+	}
+	req.Header.Set("Authorization", typ+" "+t.AccessToken)
+}
+
+// AuthCodeOption is an option passed to AuthCodeURL or Exchange.
+type AuthCodeOption struct {
+	Key, Value string
+}
+
+// SetAuthCodeOption build a AuthCodeOption.
+func SetAuthCodeOption(key, value string) AuthCodeOption {
+	return AuthCodeOption{Key: key, Value: value}
+}
+
+// AuthCodeURL returns a URL to the authorization endpoint that asks for
+// permissions for the required scopes explicitly.
+func (c *Config) AuthCodeURL(state string, opts ...AuthCodeOption) string {
+	u, err := url.Parse(c.AuthURL)
+	if err != nil {
+		return c.AuthURL
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ClientID)
+	if state != "" {
+		q.Set("state", state)
+	}
+	if len(c.Scopes) > 0 {
+		q.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	for _, opt := range opts {
+		q.Set(opt.Key, opt.Value)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Exchange converts an authorization code into a Token.
+func (c *Config) Exchange(ctx context.Context, code string, opts ...AuthCodeOption) (*Token, error) {
+	v := url.Values{"grant_type": {"authorization_code"}, "code": {code}}
+	for _, opt := range opts {
+		v.Set(opt.Key, opt.Value)
+	}
+	return c.token(ctx, v)
+}
+
+// TokenSource returns a RefreshableTokenSource that obtains tokens via the
+// client-credentials grant, refreshing automatically via the refresh-token
+// grant once a refresh token has been issued.
+func (c *Config) TokenSource(ctx context.Context) *RefreshableTokenSource {
+	return NewRefreshableTokenSource(&clientCredentialsSource{ctx: ctx, cfg: c})
+}
+
+// Client returns an *http.Client whose requests are authenticated using this
+// Config's token source.
+func (c *Config) Client(ctx context.Context) *http.Client {
+	return (&auth.TokenAuthTransport{Source: c.TokenSource(ctx)}).Client()
+}
+
+// clientCredentialsSource implements auth.TokenSource using the
+// client-credentials grant, falling back to the refresh-token grant once a
+// refresh token is available.
+type clientCredentialsSource struct {
+	ctx          context.Context
+	cfg          *Config
+	refreshToken string
+}
+
+// Token returns a token or an error.
+func (s *clientCredentialsSource) Token() (auth.Token, error) {
+	v := url.Values{}
+	if s.refreshToken != "" {
+		v.Set("grant_type", "refresh_token")
+		v.Set("refresh_token", s.refreshToken)
+	} else {
+		v.Set("grant_type", "client_credentials")
+	}
+	t, err := s.cfg.token(s.ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if t.RefreshToken != "" {
+		s.refreshToken = t.RefreshToken
+	}
+	return t, nil
+}
+
+// token POSTs v (plus scopes and EndpointParams) to TokenURL and parses the response.
+func (c *Config) token(ctx context.Context, v url.Values) (*Token, error) {
+	if len(c.Scopes) > 0 {
+		v.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	for k := range c.EndpointParams {
+		v.Set(k, c.EndpointParams.Get(k))
+	}
+
+	if c.AuthStyle == AuthStyleBody {
+		v.Set("client_id", c.ClientID)
+		v.Set("client_secret", c.ClientSecret)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: new token request error: %w", err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.AuthStyle == AuthStyleHeader {
+		req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: token request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: read token response error: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("oauth2: token request returned status %d: %s", resp.StatusCode, body)
+	}
+	return parseTokenResponse(resp.Header.Get("Content-Type"), body)
+}
+
+// parseTokenResponse parses a token response body, which may be encoded as
+// JSON or as application/x-www-form-urlencoded depending on contentType.
+func parseTokenResponse(contentType string, body []byte) (*Token, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	var accessToken, tokenType, refreshToken string
+	var expiresIn int64
+
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "" {
+		v, err := url.ParseQuery(string(body))
+		if err == nil && (v.Get("access_token") != "" || mediaType == "application/x-www-form-urlencoded") {
+			accessToken = v.Get("access_token")
+			tokenType = v.Get("token_type")
+			refreshToken = v.Get("refresh_token")
+			expiresIn, _ = strconv.ParseInt(v.Get("expires_in"), 10, 64)
+			return newToken(accessToken, tokenType, refreshToken, expiresIn)
+		}
+	}
+
+	var raw struct {
+		AccessToken  string      `json:"access_token"`
+		TokenType    string      `json:"token_type"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    interface{} `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("oauth2: unmarshal token response error: %w", err)
+	}
+	switch e := raw.ExpiresIn.(type) {
+	case float64:
+		expiresIn = int64(e)
+	case string:
+		expiresIn, _ = strconv.ParseInt(e, 10, 64)
+	}
+	return newToken(raw.AccessToken, raw.TokenType, raw.RefreshToken, expiresIn)
+}
+
+func newToken(accessToken, tokenType, refreshToken string, expiresIn int64) (*Token, error) {
+	if accessToken == "" {
+		return nil, errors.New("oauth2: token response missing access_token")
+	}
+	t := &Token{AccessToken: accessToken, TokenType: tokenType, RefreshToken: refreshToken}
+	if expiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+// RefreshableTokenSource wraps a base TokenSource and caches the token it
+// returns, only calling through to the base source again once the cached
+// token is no longer valid.
+type RefreshableTokenSource struct {
+	mu     sync.Mutex
+	Source auth.TokenSource
+
+	token auth.Token
+}
+
+// NewRefreshableTokenSource creates a RefreshableTokenSource wrapping source.
+func NewRefreshableTokenSource(source auth.TokenSource) *RefreshableTokenSource {
+	return &RefreshableTokenSource{Source: source}
+}
+
+// Token returns a token or an error.
+func (s *RefreshableTokenSource) Token() (auth.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.Valid() {
+		return s.token, nil
+	}
+	t, err := s.Source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: refresh token error: %w", err)
+	}
+	s.token = t
+	return t, nil
+}
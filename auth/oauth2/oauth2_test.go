@@ -0,0 +1,205 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/teletraan/httpx/auth"
+)
+
+type fakeSource struct {
+	calls int
+	next  func(calls int) (auth.Token, error)
+}
+
+func (f *fakeSource) Token() (auth.Token, error) {
+	f.calls++
+	return f.next(f.calls)
+}
+
+func TestRefreshableTokenSourceCachesValidToken(t *testing.T) {
+	fake := &fakeSource{next: func(int) (auth.Token, error) {
+		return &Token{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, nil
+	}}
+	src := NewRefreshableTokenSource(fake)
+
+	if _, err := src.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if _, err := src.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("calls = %d, want 1 (cached token should not trigger a refresh)", fake.calls)
+	}
+}
+
+func TestRefreshableTokenSourceRefreshesExpiredToken(t *testing.T) {
+	fake := &fakeSource{next: func(calls int) (auth.Token, error) {
+		if calls == 1 {
+			return &Token{AccessToken: "expired", Expiry: time.Now().Add(-time.Hour)}, nil
+		}
+		return &Token{AccessToken: "fresh", Expiry: time.Now().Add(time.Hour)}, nil
+	}}
+	src := NewRefreshableTokenSource(fake)
+
+	if _, err := src.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	tok, err := src.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if got := tok.(*Token).AccessToken; got != "fresh" {
+		t.Fatalf("AccessToken = %q, want %q", got, "fresh")
+	}
+	if fake.calls != 2 {
+		t.Fatalf("calls = %d, want 2 (expired cached token should trigger a refresh)", fake.calls)
+	}
+
+	if _, err := src.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if fake.calls != 2 {
+		t.Fatalf("calls = %d, want 2 (valid cached token should not trigger another refresh)", fake.calls)
+	}
+}
+
+func TestConfigTokenSourceJSONResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("grant_type = %q, want client_credentials", got)
+		}
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "id" || pass != "secret" {
+			t.Errorf("BasicAuth() = (%q, %q, %v), want (id, secret, true)", user, pass, ok)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "tok123",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.URL, AuthStyle: AuthStyleHeader}
+	tok, err := cfg.TokenSource(context.Background()).Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	ot := tok.(*Token)
+	if ot.AccessToken != "tok123" {
+		t.Fatalf("AccessToken = %q, want tok123", ot.AccessToken)
+	}
+	if !ot.Expiry.After(time.Now()) {
+		t.Fatalf("Expiry = %v, want in the future", ot.Expiry)
+	}
+}
+
+func TestConfigTokenFormEncodedResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		io.WriteString(w, "access_token=tok456&token_type=bearer&expires_in=60")
+	}))
+	defer srv.Close()
+
+	cfg := &Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.URL, AuthStyle: AuthStyleBody}
+	tok, err := cfg.token(context.Background(), url.Values{"grant_type": {"client_credentials"}})
+	if err != nil {
+		t.Fatalf("token() error = %v", err)
+	}
+	if tok.AccessToken != "tok456" {
+		t.Fatalf("AccessToken = %q, want tok456", tok.AccessToken)
+	}
+}
+
+func TestConfigAuthCodeURL(t *testing.T) {
+	cfg := &Config{
+		ClientID: "id",
+		AuthURL:  "https://provider.example.com/authorize",
+		Scopes:   []string{"read", "write"},
+	}
+
+	got := cfg.AuthCodeURL("xyz", SetAuthCodeOption("prompt", "consent"))
+
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", got, err)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{
+		"response_type": "code",
+		"client_id":     "id",
+		"state":         "xyz",
+		"scope":         "read write",
+		"prompt":        "consent",
+	} {
+		if val := q.Get(key); val != want {
+			t.Fatalf("query[%q] = %q, want %q (full URL: %s)", key, val, want, got)
+		}
+	}
+}
+
+func TestConfigExchange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "authorization_code" {
+			t.Errorf("grant_type = %q, want authorization_code", got)
+		}
+		if got := r.PostForm.Get("code"); got != "auth-code-123" {
+			t.Errorf("code = %q, want auth-code-123", got)
+		}
+		if got := r.PostForm.Get("redirect_uri"); got != "https://app.example.com/callback" {
+			t.Errorf("redirect_uri = %q, want https://app.example.com/callback", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "tok789",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.URL, AuthStyle: AuthStyleBody}
+	tok, err := cfg.Exchange(context.Background(), "auth-code-123",
+		SetAuthCodeOption("redirect_uri", "https://app.example.com/callback"))
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if tok.AccessToken != "tok789" {
+		t.Fatalf("AccessToken = %q, want tok789", tok.AccessToken)
+	}
+}
+
+func TestTokenValidExpiry(t *testing.T) {
+	cases := []struct {
+		name  string
+		token *Token
+		want  bool
+	}{
+		{"no expiry", &Token{AccessToken: "a"}, true},
+		{"future expiry", &Token{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, true},
+		{"past expiry", &Token{AccessToken: "a", Expiry: time.Now().Add(-time.Hour)}, false},
+		{"empty access token", &Token{Expiry: time.Now().Add(time.Hour)}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.token.Valid(); got != tc.want {
+				t.Fatalf("Valid() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
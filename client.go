@@ -3,7 +3,6 @@ package httpx
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -13,11 +12,24 @@ import (
 	"strings"
 )
 
+// ErrorDecoder decodes a 4xx/5xx http.Response into an error, letting
+// callers whose APIs return structured error bodies (e.g. RFC 7807
+// problem+json) get back a typed error instead of a raw string.
+type ErrorDecoder func(*http.Response) error
+
 // Client wrap http request.
 type Client struct {
 	BaseURL   *url.URL
 	UserAgent string
 
+	// Codec encodes request bodies and decodes response bodies.
+	// It defaults to JSONCodec.
+	Codec Codec
+
+	// ErrorDecoder, if set, is used to decode 4xx/5xx response bodies
+	// instead of storing them as a raw string in Response.ErrMessage.
+	ErrorDecoder ErrorDecoder
+
 	httpClient *http.Client
 }
 
@@ -42,7 +54,21 @@ func (c *Client) Copy(httpClient *http.Client) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
-	return &Client{BaseURL: c.BaseURL, UserAgent: c.UserAgent, httpClient: httpClient}
+	return &Client{
+		BaseURL:      c.BaseURL,
+		UserAgent:    c.UserAgent,
+		Codec:        c.Codec,
+		ErrorDecoder: c.ErrorDecoder,
+		httpClient:   httpClient,
+	}
+}
+
+// codec returns c.Codec, defaulting to JSONCodec.
+func (c *Client) codec() Codec {
+	if c.Codec != nil {
+		return c.Codec
+	}
+	return JSONCodec
 }
 
 // NewRequest creates an json API request. A relative URL can be provided in urlStr,
@@ -67,7 +93,7 @@ func (c *Client) NewRequest(method, urlStr string, params map[string]string, bod
 	var buf io.ReadWriter
 	if body != nil {
 		buf = new(bytes.Buffer)
-		if err := json.NewEncoder(buf).Encode(body); err != nil {
+		if err := c.codec().Encode(buf, body); err != nil {
 			return nil, fmt.Errorf("httpx new request error: %w", err)
 		}
 	}
@@ -76,9 +102,9 @@ func (c *Client) NewRequest(method, urlStr string, params map[string]string, bod
 		return nil, fmt.Errorf("httpx new request error: %w", err)
 	}
 	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", c.codec().ContentType())
 	}
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", c.codec().ContentType())
 	req.Header.Set("User-Agent", c.UserAgent)
 	return req, nil
 }
@@ -151,6 +177,9 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	response := &Response{Response: resp}
 
 	if response.HasError() {
+		if c.ErrorDecoder != nil {
+			return nil, c.ErrorDecoder(resp)
+		}
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			return nil, fmt.Errorf("httpx do error: %w", err)
@@ -165,7 +194,7 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 		return response, nil
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+	if err := c.codec().Decode(resp.Body, v); err != nil {
 		return response, err
 	}
 	return response, nil
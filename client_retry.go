@@ -0,0 +1,31 @@
+package httpx
+
+import (
+	"net/http"
+
+	"github.com/teletraan/httpx/retry"
+)
+
+// WithRetry returns a copy of c whose requests go through a copy of rt,
+// retrying idempotent requests on top of c's existing http.Client
+// transport. If rt is nil, a RetryTransport with its defaults is used.
+//
+// rt itself is never modified, so the same *retry.RetryTransport can be
+// passed to WithRetry for multiple Clients without them fighting over its
+// Transport field.
+func (c *Client) WithRetry(rt *retry.RetryTransport) *Client {
+	var rt2 retry.RetryTransport
+	if rt != nil {
+		rt2 = *rt
+	}
+
+	base := c.httpClient
+	if base == nil {
+		base = http.DefaultClient
+	}
+	rt2.Transport = base.Transport
+
+	hc := *base
+	hc.Transport = &rt2
+	return c.Copy(&hc)
+}
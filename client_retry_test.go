@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/teletraan/httpx/retry"
+)
+
+type recordingRoundTripper struct {
+	name  string
+	calls int
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.calls++
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
+
+func TestWithRetrySharedPolicyDoesNotCrossWireClients(t *testing.T) {
+	shared := &retry.RetryTransport{}
+
+	transportA := &recordingRoundTripper{name: "A"}
+	clientA, err := New("http://a.example.com", "", &http.Client{Transport: transportA})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	withRetryA := clientA.WithRetry(shared)
+
+	transportB := &recordingRoundTripper{name: "B"}
+	clientB, err := New("http://b.example.com", "", &http.Client{Transport: transportB})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	withRetryB := clientB.WithRetry(shared)
+
+	reqA, err := withRetryA.NewRequest(http.MethodGet, "/", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if _, err := withRetryA.httpClient.Do(reqA); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if transportA.calls != 1 {
+		t.Fatalf("transportA.calls = %d, want 1 (client A's request should go through its own transport)", transportA.calls)
+	}
+	if transportB.calls != 0 {
+		t.Fatalf("transportB.calls = %d, want 0 (client A's request must not be routed through client B's transport)", transportB.calls)
+	}
+
+	reqB, err := withRetryB.NewRequest(http.MethodGet, "/", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if _, err := withRetryB.httpClient.Do(reqB); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if transportA.calls != 1 {
+		t.Fatalf("transportA.calls = %d, want 1 (client B's request must not be routed through client A's transport)", transportA.calls)
+	}
+	if transportB.calls != 1 {
+		t.Fatalf("transportB.calls = %d, want 1 (client B's request should go through its own transport)", transportB.calls)
+	}
+}
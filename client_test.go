@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientDoDecodesWithDefaultJSONCodec(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(codecTestValue{Name: "gopher", Age: 11})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	req, err := c.NewRequest(http.MethodGet, "/", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	var got codecTestValue
+	if _, err := c.Do(context.Background(), req, &got); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if want := (codecTestValue{Name: "gopher", Age: 11}); got != want {
+		t.Fatalf("Do() decoded = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientDoDecodesWithCustomCodec(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", FormCodec.ContentType())
+		io.WriteString(w, "name=gopher&age=11")
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c.Codec = FormCodec
+
+	req, err := c.NewRequest(http.MethodGet, "/", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if got := req.Header.Get("Accept"); got != FormCodec.ContentType() {
+		t.Fatalf("Accept header = %q, want %q", got, FormCodec.ContentType())
+	}
+
+	var got codecTestValue
+	if _, err := c.Do(context.Background(), req, &got); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if want := (codecTestValue{Name: "gopher", Age: 11}); got != want {
+		t.Fatalf("Do() decoded = %+v, want %+v", got, want)
+	}
+}
+
+type problemDetails struct {
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
+
+func (p *problemDetails) Error() string {
+	return p.Title
+}
+
+func TestClientDoRoutesErrorBodyThroughErrorDecoder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(problemDetails{Title: "bad input", Status: http.StatusBadRequest})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c.ErrorDecoder = func(resp *http.Response) error {
+		var p problemDetails
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			return err
+		}
+		return &p
+	}
+
+	req, err := c.NewRequest(http.MethodGet, "/", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	var v map[string]interface{}
+	_, err = c.Do(context.Background(), req, &v)
+	if err == nil {
+		t.Fatal("Do() error = nil, want an error decoded via ErrorDecoder")
+	}
+
+	var p *problemDetails
+	if !errors.As(err, &p) {
+		t.Fatalf("Do() error = %v (%T), want a *problemDetails", err, err)
+	}
+	if p.Title != "bad input" || p.Status != http.StatusBadRequest {
+		t.Fatalf("decoded error = %+v, want {bad input 400}", p)
+	}
+
+	if _, ok := err.(*Response); ok {
+		t.Fatal("Do() error should not be the default *Response when ErrorDecoder is set")
+	}
+}
+
+func TestClientDoFallsBackToErrMessageWithoutErrorDecoder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, "boom")
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	req, err := c.NewRequest(http.MethodGet, "/", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	var v map[string]interface{}
+	_, err = c.Do(context.Background(), req, &v)
+	if err == nil {
+		t.Fatal("Do() error = nil, want an error for a 5xx response")
+	}
+	resp, ok := err.(*Response)
+	if !ok {
+		t.Fatalf("Do() error = %v (%T), want a *Response", err, err)
+	}
+	if resp.ErrMessage != "boom" {
+		t.Fatalf("ErrMessage = %q, want boom", resp.ErrMessage)
+	}
+}
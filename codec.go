@@ -0,0 +1,197 @@
+package httpx
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// Codec encodes request bodies and decodes response bodies for
+// Client.NewRequest and Client.Do.
+type Codec interface {
+	// Encode writes v to w.
+	Encode(w io.Writer, v interface{}) error
+	// Decode reads from r into v.
+	Decode(r io.Reader, v interface{}) error
+	// ContentType returns the Content-Type/Accept header value to use for
+	// bodies handled by this Codec.
+	ContentType() string
+}
+
+// JSONCodec encodes and decodes request/response bodies as JSON.
+// It is the default Codec used by Client.
+var JSONCodec Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func (jsonCodec) ContentType() string { return "application/json" }
+
+// XMLCodec encodes and decodes request/response bodies as XML.
+var XMLCodec Codec = xmlCodec{}
+
+type xmlCodec struct{}
+
+func (xmlCodec) Encode(w io.Writer, v interface{}) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func (xmlCodec) Decode(r io.Reader, v interface{}) error {
+	if err := xml.NewDecoder(r).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func (xmlCodec) ContentType() string { return "application/xml" }
+
+// FormCodec encodes and decodes request/response bodies as
+// application/x-www-form-urlencoded, using a struct's exported fields
+// (optionally renamed with a `form:"name"` tag). It supports string, bool
+// and signed integer fields, and pointers to those, and does not support
+// unsigned integers, nested structs or slices.
+var FormCodec Codec = formCodec{}
+
+type formCodec struct{}
+
+func (formCodec) Encode(w io.Writer, v interface{}) error {
+	values, err := formValues(v)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, values.Encode())
+	return err
+}
+
+func (formCodec) Decode(r io.Reader, v interface{}) error {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return fmt.Errorf("httpx: parse form response error: %w", err)
+	}
+	return formDecode(values, v)
+}
+
+func (formCodec) ContentType() string { return "application/x-www-form-urlencoded" }
+
+// formFieldName returns the form field name for a struct field, honoring a
+// `form:"name"` tag.
+func formFieldName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("form")
+	if tag == "-" {
+		return "", true
+	}
+	if tag != "" {
+		return tag, false
+	}
+	return field.Name, false
+}
+
+func formValues(v interface{}) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("httpx: form codec requires a struct, got %T", v)
+	}
+
+	values := url.Values{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, skip := formFieldName(field)
+		if skip {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		switch fv.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			values.Set(name, fmt.Sprintf("%v", fv.Interface()))
+		default:
+			return nil, fmt.Errorf("httpx: form codec: field %s has unsupported type %s", field.Name, fv.Kind())
+		}
+	}
+	return values, nil
+}
+
+func formDecode(values url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("httpx: form codec requires a non-nil pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("httpx: form codec requires a pointer to struct, got %T", v)
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, skip := formFieldName(field)
+		if skip {
+			continue
+		}
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(raw)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("httpx: form codec: field %s: %w", field.Name, err)
+			}
+			fv.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("httpx: form codec: field %s: %w", field.Name, err)
+			}
+			fv.SetInt(n)
+		default:
+			return fmt.Errorf("httpx: form codec: field %s has unsupported type %s", field.Name, fv.Kind())
+		}
+	}
+	return nil
+}
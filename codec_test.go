@@ -0,0 +1,98 @@
+package httpx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type codecTestValue struct {
+	Name string `json:"name" xml:"name" form:"name"`
+	Age  int    `json:"age" xml:"age" form:"age"`
+}
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	in := codecTestValue{Name: "gopher", Age: 11}
+	var buf bytes.Buffer
+	if err := JSONCodec.Encode(&buf, in); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var out codecTestValue
+	if err := JSONCodec.Decode(&buf, &out); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if out != in {
+		t.Fatalf("Decode() = %+v, want %+v", out, in)
+	}
+	if JSONCodec.ContentType() != "application/json" {
+		t.Fatalf("ContentType() = %q, want application/json", JSONCodec.ContentType())
+	}
+}
+
+func TestXMLCodecRoundTrip(t *testing.T) {
+	in := codecTestValue{Name: "gopher", Age: 11}
+	var buf bytes.Buffer
+	if err := XMLCodec.Encode(&buf, in); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	// sanity check it's actually XML, not some other encoding.
+	encoded := buf.String()
+	if !strings.Contains(encoded, "<name>gopher</name>") {
+		t.Fatalf("encoded XML = %q, want it to contain <name>gopher</name>", encoded)
+	}
+
+	var out codecTestValue
+	if err := XMLCodec.Decode(&buf, &out); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if out != in {
+		t.Fatalf("Decode() = %+v, want %+v", out, in)
+	}
+	if XMLCodec.ContentType() != "application/xml" {
+		t.Fatalf("ContentType() = %q, want application/xml", XMLCodec.ContentType())
+	}
+}
+
+func TestFormCodecRoundTrip(t *testing.T) {
+	in := codecTestValue{Name: "gopher", Age: 11}
+	var buf bytes.Buffer
+	if err := FormCodec.Encode(&buf, in); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "name=gopher") || !strings.Contains(buf.String(), "age=11") {
+		t.Fatalf("encoded form = %q, want name=gopher and age=11", buf.String())
+	}
+
+	var out codecTestValue
+	if err := FormCodec.Decode(&buf, &out); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if out != in {
+		t.Fatalf("Decode() = %+v, want %+v", out, in)
+	}
+	if FormCodec.ContentType() != "application/x-www-form-urlencoded" {
+		t.Fatalf("ContentType() = %q, want application/x-www-form-urlencoded", FormCodec.ContentType())
+	}
+}
+
+func TestFormCodecEncodeRejectsUnsupportedFieldKinds(t *testing.T) {
+	type withSlice struct {
+		Tags []string `form:"tags"`
+	}
+	var buf bytes.Buffer
+	err := FormCodec.Encode(&buf, withSlice{Tags: []string{"a", "b"}})
+	if err == nil {
+		t.Fatal("Encode() error = nil, want an error for an unsupported slice field")
+	}
+
+	type withNested struct {
+		Inner codecTestValue `form:"inner"`
+	}
+	buf.Reset()
+	err = FormCodec.Encode(&buf, withNested{Inner: codecTestValue{Name: "x"}})
+	if err == nil {
+		t.Fatal("Encode() error = nil, want an error for an unsupported nested struct field")
+	}
+}
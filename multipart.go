@@ -0,0 +1,103 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// FilePart describes a file to stream into a multipart request built by
+// Client.NewMultipartRequest.
+type FilePart struct {
+	// FieldName is the form field name the file is submitted under.
+	FieldName string
+	// FileName is the filename reported in the part's Content-Disposition.
+	FileName string
+	// ContentType is the part's Content-Type. It is omitted if empty.
+	ContentType string
+	// Reader supplies the file's content. It is read once, as the request
+	// body is written.
+	Reader io.Reader
+	// Size is the file's length, if known. It is currently informational
+	// only: the overall request streams with a chunked body regardless.
+	Size int64
+}
+
+// NewMultipartRequest creates a streaming multi-part API request: fields and
+// files are written into an io.Pipe by a goroutine as the request body is
+// read, so large uploads don't need to be buffered into memory first. A
+// relative URL can be provided in urlStr, in which case it is resolved
+// relative to the BaseURL of the Client. Relative URLs should always be
+// specified with a preceding slash.
+//
+// req.GetBody is populated only when there are no files, since file Readers
+// are consumed as the body streams and generally can't be replayed.
+func (c *Client) NewMultipartRequest(method, urlStr string, fields map[string]string, files []FilePart) (*http.Request, error) {
+	if !strings.HasPrefix(urlStr, "/") {
+		return nil, fmt.Errorf("httpx new multipart request error: url must have a preceding slash, but %q does not", urlStr)
+	}
+	u, err := c.BaseURL.Parse(urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("httpx new multipart request error: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, fields, files))
+	}()
+
+	req, err := http.NewRequest(method, u.String(), pr)
+	if err != nil {
+		return nil, fmt.Errorf("httpx new multipart request error: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	if len(files) == 0 {
+		boundary := writer.Boundary()
+		req.GetBody = func() (io.ReadCloser, error) {
+			pr2, pw2 := io.Pipe()
+			w2 := multipart.NewWriter(pw2)
+			if err := w2.SetBoundary(boundary); err != nil {
+				return nil, fmt.Errorf("httpx new multipart request error: %w", err)
+			}
+			go func() {
+				pw2.CloseWithError(writeMultipart(w2, fields, nil))
+			}()
+			return pr2, nil
+		}
+	}
+	return req, nil
+}
+
+// writeMultipart writes fields and then files into w, closing w once done.
+// CloseWithError on the underlying pipe treats a nil error as success.
+func writeMultipart(w *multipart.Writer, fields map[string]string, files []FilePart) error {
+	defer w.Close()
+
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			return fmt.Errorf("write field %q error: %w", name, err)
+		}
+	}
+	for _, f := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, f.FieldName, f.FileName))
+		if f.ContentType != "" {
+			header.Set("Content-Type", f.ContentType)
+		}
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return fmt.Errorf("create part %q error: %w", f.FieldName, err)
+		}
+		if _, err := io.Copy(part, f.Reader); err != nil {
+			return fmt.Errorf("copy file %q error: %w", f.FieldName, err)
+		}
+	}
+	return nil
+}
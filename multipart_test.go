@@ -0,0 +1,110 @@
+package httpx
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewMultipartRequestStreamsFieldsAndFiles(t *testing.T) {
+	c, err := New("http://example.com", "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	fields := map[string]string{"name": "gopher"}
+	files := []FilePart{{
+		FieldName:   "file",
+		FileName:    "a.txt",
+		ContentType: "text/plain",
+		Reader:      strings.NewReader("hello world"),
+	}}
+
+	req, err := c.NewMultipartRequest(http.MethodPost, "/upload", fields, files)
+	if err != nil {
+		t.Fatalf("NewMultipartRequest() error = %v", err)
+	}
+
+	_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("ParseMediaType() error = %v", err)
+	}
+
+	mr := multipart.NewReader(req.Body, params["boundary"])
+	form, err := mr.ReadForm(10 << 20)
+	if err != nil {
+		t.Fatalf("ReadForm() error = %v", err)
+	}
+
+	if got := form.Value["name"]; len(got) != 1 || got[0] != "gopher" {
+		t.Fatalf("form field name = %v, want [gopher]", got)
+	}
+
+	fhs := form.File["file"]
+	if len(fhs) != 1 {
+		t.Fatalf("form file count = %d, want 1", len(fhs))
+	}
+	if fhs[0].Filename != "a.txt" {
+		t.Fatalf("Filename = %q, want a.txt", fhs[0].Filename)
+	}
+	f, err := fhs[0].Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(data) != "hello world" {
+		t.Fatalf("file content = %q, want %q", data, "hello world")
+	}
+}
+
+func TestNewMultipartRequestGetBodyWithoutFiles(t *testing.T) {
+	c, err := New("http://example.com", "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req, err := c.NewMultipartRequest(http.MethodPost, "/upload", map[string]string{"a": "1"}, nil)
+	if err != nil {
+		t.Fatalf("NewMultipartRequest() error = %v", err)
+	}
+	if req.GetBody == nil {
+		t.Fatal("GetBody = nil, want set when there are no files")
+	}
+
+	for i := 0; i < 2; i++ {
+		body, err := req.GetBody()
+		if err != nil {
+			t.Fatalf("GetBody() error = %v", err)
+		}
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if !strings.Contains(string(data), `name="a"`) || !strings.Contains(string(data), "1") {
+			t.Fatalf("GetBody() content = %q, want it to contain field a=1", data)
+		}
+	}
+}
+
+func TestNewMultipartRequestGetBodyNilWithFiles(t *testing.T) {
+	c, err := New("http://example.com", "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	files := []FilePart{{FieldName: "file", FileName: "a.txt", Reader: strings.NewReader("x")}}
+	req, err := c.NewMultipartRequest(http.MethodPost, "/upload", nil, files)
+	if err != nil {
+		t.Fatalf("NewMultipartRequest() error = %v", err)
+	}
+	if req.GetBody != nil {
+		t.Fatal("GetBody != nil, want nil when files are streamed (not replayable)")
+	}
+}
@@ -0,0 +1,232 @@
+// Package retry implements a retry/backoff http.RoundTripper, composable
+// with other transports such as auth.TokenAuthTransport.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultStatusCodes are the response status codes retried when
+// RetryTransport.StatusCodes is unset.
+var DefaultStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Defaults for RetryTransport's tunables.
+const (
+	DefaultMaxAttempts = 4
+	DefaultBaseDelay   = 200 * time.Millisecond
+	DefaultMaxDelay    = 5 * time.Second
+)
+
+// RetryTransport is an http.RoundTripper that retries idempotent requests
+// (GET, HEAD, OPTIONS, PUT, DELETE) on network errors and configurable
+// response status codes, using exponential backoff with jitter.
+//
+// Retrying a request that has a body requires req.GetBody to be set so the
+// body can be replayed; auth.TokenAuthTransport's cloneRequest populates it.
+// Requests with a body but no GetBody are sent once and never retried.
+type RetryTransport struct {
+	// Transport is the underlying HTTP transport to use when making
+	// requests. It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+
+	// StatusCodes are the response status codes that trigger a retry.
+	// Defaults to DefaultStatusCodes.
+	StatusCodes []int
+
+	// MaxAttempts caps the total number of attempts, including the first.
+	// Defaults to DefaultMaxAttempts.
+	MaxAttempts int
+
+	// BaseDelay and MaxDelay bound the exponential backoff: the delay
+	// after failed attempt n (counting from 0) is BaseDelay*2^n, capped at
+	// MaxDelay, plus jitter in
+	// [0, BaseDelay). A Retry-After response header, if present, overrides
+	// the computed delay. Default to DefaultBaseDelay and DefaultMaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A request with a body we can't rewind (no GetBody) can only ever be
+	// sent once: the first attempt's transport drains (and, for pipe-backed
+	// bodies, closes) req.Body, so retrying it would silently send an
+	// empty/EOF body instead of the original payload.
+	if !isIdempotent(req.Method) || hasNonReplayableBody(req) {
+		return t.transport().RoundTrip(req)
+	}
+
+	maxAttempts := t.maxAttempts()
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq, err = rewindRequest(req)
+			if err != nil {
+				return nil, fmt.Errorf("retry: %w", err)
+			}
+		}
+
+		resp, err = t.transport().RoundTrip(attemptReq)
+		if !t.shouldRetry(resp, err) {
+			return resp, err
+		}
+		if attempt == maxAttempts-1 {
+			break
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp); ok {
+				delay = d
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, fmt.Errorf("retry: %w", req.Context().Err())
+		case <-time.After(delay):
+		}
+	}
+	return resp, err
+}
+
+// rewindRequest returns a shallow copy of req with its body replayed from
+// req.GetBody, so a retried attempt doesn't send an already-consumed body.
+// Callers must only reach this once RoundTrip has established that req has
+// no body or has a replayable one (req.GetBody != nil).
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("retry: request has a body but no GetBody, cannot replay it")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewind request body error: %w", err)
+	}
+	req2 := new(http.Request)
+	*req2 = *req
+	req2.Body = body
+	return req2, nil
+}
+
+// shouldRetry reports whether resp/err warrant another attempt.
+func (t *RetryTransport) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	for _, code := range t.statusCodes() {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff computes the delay after the given zero-based attempt has failed,
+// before the next one: an exponential base*2^attempt capped at MaxDelay,
+// plus jitter in [0, base).
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	base := t.baseDelay()
+	maxDelay := t.maxDelay()
+
+	delay := base * time.Duration(math.Pow(2, float64(attempt)))
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if base > 0 {
+		delay += time.Duration(rand.Int63n(int64(base)))
+	}
+	return delay
+}
+
+// retryAfter parses a Retry-After header in either its seconds or
+// HTTP-date form.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
+
+// hasNonReplayableBody reports whether req carries a body that can't be
+// replayed on a retry attempt (neither empty nor backed by a GetBody).
+func hasNonReplayableBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.GetBody == nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *RetryTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (t *RetryTransport) statusCodes() []int {
+	if t.StatusCodes != nil {
+		return t.StatusCodes
+	}
+	return DefaultStatusCodes
+}
+
+func (t *RetryTransport) maxAttempts() int {
+	if t.MaxAttempts > 0 {
+		return t.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+func (t *RetryTransport) baseDelay() time.Duration {
+	if t.BaseDelay > 0 {
+		return t.BaseDelay
+	}
+	return DefaultBaseDelay
+}
+
+func (t *RetryTransport) maxDelay() time.Duration {
+	if t.MaxDelay > 0 {
+		return t.MaxDelay
+	}
+	return DefaultMaxDelay
+}
+
+// Client returns an *http.Client whose requests go through this
+// RetryTransport.
+func (t *RetryTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
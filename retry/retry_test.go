@@ -0,0 +1,133 @@
+package retry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransportRetriesOnStatusCode(t *testing.T) {
+	attempts := 0
+	rt := &RetryTransport{
+		BaseDelay: time.Millisecond,
+		MaxDelay:  time.Millisecond,
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			status := http.StatusServiceUnavailable
+			if attempts == 3 {
+				status = http.StatusOK
+			}
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: http.NoBody}, nil
+		}),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRetryTransportGivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	rt := &RetryTransport{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}, nil
+		}),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+// A request with a body but no GetBody can't be replayed, so it must only
+// ever be sent once even if the response is retryable.
+func TestRetryTransportDoesNotRetryNonReplayableBody(t *testing.T) {
+	attempts := 0
+	rt := &RetryTransport{
+		BaseDelay: time.Millisecond,
+		MaxDelay:  time.Millisecond,
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}, nil
+		}),
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "http://example.com", strings.NewReader("payload"))
+	req.GetBody = nil
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1 for a non-replayable body", attempts)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "2")
+
+	d, ok := retryAfter(resp)
+	if !ok {
+		t.Fatal("retryAfter() ok = false, want true")
+	}
+	if d != 2*time.Second {
+		t.Fatalf("retryAfter() = %v, want 2s", d)
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(5*time.Second).UTC().Format(http.TimeFormat))
+
+	d, ok := retryAfter(resp)
+	if !ok {
+		t.Fatal("retryAfter() ok = false, want true")
+	}
+	if d <= 0 || d > 6*time.Second {
+		t.Fatalf("retryAfter() = %v, want roughly 5s", d)
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	rt := &RetryTransport{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	if d := rt.backoff(0); d < 100*time.Millisecond || d >= 200*time.Millisecond {
+		t.Fatalf("backoff(0) = %v, want in [100ms, 200ms)", d)
+	}
+	if d := rt.backoff(5); d < 300*time.Millisecond || d >= 400*time.Millisecond {
+		t.Fatalf("backoff(5) = %v, want in [300ms, 400ms) once capped", d)
+	}
+}